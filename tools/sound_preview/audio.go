@@ -0,0 +1,10 @@
+package main
+
+import "github.com/yencarnacion/exit-indicator-aggr/tools/sound_preview/decoder"
+
+// decodeAudioFile loads a file sound in-process (no tmpfile, no system
+// player) at the tool's sampleRate so it can be mixed into the PortAudio
+// callback directly, same as a synthesized alert.
+func decodeAudioFile(path string) ([]int16, int, int, error) {
+	return decoder.Decode(path, sampleRate)
+}
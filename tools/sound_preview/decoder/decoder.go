@@ -0,0 +1,102 @@
+// Package decoder loads file sounds (wav/mp3/flac/ogg/opus) in-process,
+// downmixed to mono and resampled to a target rate, so both the exec and
+// PortAudio playback backends can treat every file sound the same way the
+// tool already treats synthesized alerts.
+package decoder
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Quality selects the resampler's windowed-sinc kernel width; higher
+// quality costs more CPU per decode, which only matters for long file
+// sounds since alerts are short.
+type Quality int
+
+const (
+	QualityLow    Quality = iota // 9-tap Hann-windowed sinc
+	QualityMedium                // 17-tap
+	QualityHigh                  // 33-tap
+)
+
+func (q Quality) taps() int {
+	switch q {
+	case QualityLow:
+		return 9
+	case QualityHigh:
+		return 33
+	default:
+		return 17
+	}
+}
+
+// Decode loads path, downmixes to mono, and resamples to targetSampleRate
+// using QualityMedium. It returns the mono samples at targetSampleRate,
+// targetSampleRate itself, and 1 channel, matching the shape file sounds
+// already flow through in playSamples.
+func Decode(path string, targetSampleRate int) ([]int16, int, int, error) {
+	return DecodeQuality(path, targetSampleRate, QualityMedium)
+}
+
+// DecodeQuality is Decode with an explicit resampler quality.
+func DecodeQuality(path string, targetSampleRate int, quality Quality) ([]int16, int, int, error) {
+	samples, sourceRate, channels, err := decodeRaw(path)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	mono := downmix(samples, channels)
+	if sourceRate != targetSampleRate {
+		mono = resample(mono, sourceRate, targetSampleRate, quality)
+	}
+	return mono, targetSampleRate, 1, nil
+}
+
+// decodeRaw dispatches to a format-specific decoder and returns its native
+// sample rate/channel count, before downmix/resample.
+func decodeRaw(path string) ([]int16, int, int, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".wav", ".wave":
+		return decodeWAV(path)
+	case ".mp3", ".mpeg":
+		return decodeMP3(path)
+	case ".flac":
+		return decodeFLAC(path)
+	case ".ogg":
+		return decodeOggVorbis(path)
+	case ".opus":
+		return decodeOpus(path)
+	default:
+		return nil, 0, 0, fmt.Errorf("unsupported audio file extension %q for %s", filepath.Ext(path), path)
+	}
+}
+
+// IsSupportedExt reports whether ext (with leading dot) can be decoded,
+// for callers that discover candidate files before decoding them.
+func IsSupportedExt(ext string) bool {
+	switch strings.ToLower(ext) {
+	case ".wav", ".wave", ".mp3", ".mpeg", ".flac", ".ogg", ".opus":
+		return true
+	default:
+		return false
+	}
+}
+
+// downmix averages interleaved channels down to mono; it's a no-op for
+// already-mono input.
+func downmix(samples []int16, channels int) []int16 {
+	if channels <= 1 {
+		return samples
+	}
+	out := make([]int16, len(samples)/channels)
+	for i := range out {
+		sum := 0
+		for c := 0; c < channels; c++ {
+			sum += int(samples[i*channels+c])
+		}
+		out[i] = int16(sum / channels)
+	}
+	return out
+}
@@ -0,0 +1,48 @@
+package decoder
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/mewkiz/flac"
+)
+
+// decodeFLAC decodes a FLAC file to interleaved int16 samples, scaling down
+// from whatever bit depth the stream uses.
+func decodeFLAC(path string) ([]int16, int, int, error) {
+	stream, err := flac.ParseFile(path)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("flac decode: %w", err)
+	}
+	defer stream.Close()
+
+	channels := int(stream.Info.NChannels)
+	rate := int(stream.Info.SampleRate)
+	shift := int(stream.Info.BitsPerSample) - 16
+
+	var out []int16
+	for {
+		frame, err := stream.ParseNext()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("flac decode: %w", err)
+		}
+		n := frame.Subframes[0].NSamples
+		for i := 0; i < n; i++ {
+			for c := 0; c < channels; c++ {
+				v := frame.Subframes[c].Samples[i]
+				switch {
+				case shift > 0:
+					v >>= uint(shift)
+				case shift < 0:
+					v <<= uint(-shift)
+				}
+				out = append(out, int16(v))
+			}
+		}
+	}
+	return out, rate, channels, nil
+}
@@ -0,0 +1,41 @@
+package decoder
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hajimehoshi/go-mp3"
+)
+
+// decodeMP3 decodes an MP3 file to interleaved 16-bit stereo samples using a
+// pure-Go decoder.
+func decodeMP3(path string) ([]int16, int, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	defer f.Close()
+
+	dec, err := mp3.NewDecoder(f)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("mp3 decode: %w", err)
+	}
+
+	var out []int16
+	buf := make([]byte, 4096)
+	for {
+		n, err := dec.Read(buf)
+		for i := 0; i+1 < n; i += 2 {
+			out = append(out, int16(binary.LittleEndian.Uint16(buf[i:i+2])))
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("mp3 decode: %w", err)
+		}
+	}
+	return out, dec.SampleRate(), 2, nil
+}
@@ -0,0 +1,28 @@
+package decoder
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jfreymuth/oggvorbis"
+)
+
+// decodeOggVorbis decodes an Ogg/Vorbis file to interleaved int16 samples.
+func decodeOggVorbis(path string) ([]int16, int, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	defer f.Close()
+
+	pcm, format, err := oggvorbis.ReadAll(f)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("ogg/vorbis decode: %w", err)
+	}
+
+	out := make([]int16, len(pcm))
+	for i, v := range pcm {
+		out[i] = clampToInt16(float64(v) * 32767.0)
+	}
+	return out, format.SampleRate, format.Channels, nil
+}
@@ -0,0 +1,51 @@
+package decoder
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// demuxOggPackets reassembles Ogg pages into the logical packets they
+// carry, following the lacing-value continuation rule from RFC 3533. It's
+// pure container parsing with no codec dependency, so it builds (and is
+// testable) regardless of whether this tool was built with libopus support.
+func demuxOggPackets(data []byte) ([][]byte, error) {
+	var packets [][]byte
+	var pending []byte
+
+	r := bytes.NewReader(data)
+	for r.Len() > 0 {
+		var capturePattern [4]byte
+		if _, err := io.ReadFull(r, capturePattern[:]); err != nil {
+			break
+		}
+		if string(capturePattern[:]) != "OggS" {
+			return nil, fmt.Errorf("bad ogg capture pattern")
+		}
+
+		// version(1) type(1) granule(8) serial(4) seq(4) crc(4) segments(1)
+		header := make([]byte, 23)
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, err
+		}
+		segCount := int(header[22])
+		segTable := make([]byte, segCount)
+		if _, err := io.ReadFull(r, segTable); err != nil {
+			return nil, err
+		}
+
+		for _, segLen := range segTable {
+			seg := make([]byte, segLen)
+			if _, err := io.ReadFull(r, seg); err != nil {
+				return nil, err
+			}
+			pending = append(pending, seg...)
+			if segLen < 255 { // packet complete
+				packets = append(packets, pending)
+				pending = nil
+			}
+		}
+	}
+	return packets, nil
+}
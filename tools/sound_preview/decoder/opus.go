@@ -0,0 +1,52 @@
+//go:build opus
+
+package decoder
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/hraban/opus.v2"
+)
+
+const opusFrameSize = 960 * 6 // 120ms at 48kHz, generously covers any frame this tool plays
+
+// decodeOpus demuxes an Ogg/Opus file's packets and decodes them with
+// libopus, producing interleaved int16 samples at the stream's native
+// 48kHz. Unlike FLAC/Vorbis, Opus has no container-neutral decoder, so the
+// minimal Ogg page parsing lives in oggdemux.go rather than in a shared
+// helper.
+func decodeOpus(path string) ([]int16, int, int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	packets, err := demuxOggPackets(data)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("opus demux: %w", err)
+	}
+	if len(packets) < 2 {
+		return nil, 0, 0, fmt.Errorf("opus stream has no audio packets")
+	}
+
+	channels := int(packets[0][9])
+	if channels != 1 && channels != 2 {
+		channels = 2
+	}
+	dec, err := opus.NewDecoder(48000, channels)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("opus decoder init: %w", err)
+	}
+
+	var out []int16
+	pcm := make([]int16, opusFrameSize*channels)
+	for _, pkt := range packets[2:] { // skip OpusHead, OpusTags
+		n, err := dec.Decode(pkt, pcm)
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("opus decode: %w", err)
+		}
+		out = append(out, pcm[:n*channels]...)
+	}
+	return out, 48000, channels, nil
+}
@@ -0,0 +1,12 @@
+//go:build !opus
+
+package decoder
+
+import "fmt"
+
+// decodeOpus is unavailable in this build: libopus/libopusfile are cgo
+// dependencies, gated behind the "opus" build tag so the rest of this
+// package builds and tests without them installed.
+func decodeOpus(path string) ([]int16, int, int, error) {
+	return nil, 0, 0, fmt.Errorf("decode %q: built without opus support (rebuild with -tags opus)", path)
+}
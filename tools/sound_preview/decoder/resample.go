@@ -0,0 +1,93 @@
+package decoder
+
+import "math"
+
+// resample converts samples from sourceRate to targetRate using a
+// windowed-sinc kernel, falling back to linear interpolation when the
+// quality-appropriate kernel would be at least as wide as the input (tiny
+// clips).
+func resample(samples []int16, sourceRate, targetRate int, quality Quality) []int16 {
+	if sourceRate == targetRate || len(samples) == 0 {
+		return samples
+	}
+	ratio := float64(sourceRate) / float64(targetRate)
+	outLen := int(float64(len(samples)) / ratio)
+	out := make([]int16, outLen)
+
+	halfTaps := quality.taps() / 2
+	if len(samples) <= quality.taps() {
+		return linearResample(samples, outLen, ratio)
+	}
+
+	for i := 0; i < outLen; i++ {
+		srcPos := float64(i) * ratio
+		center := int(srcPos)
+		sum := 0.0
+		weight := 0.0
+		for k := -halfTaps; k <= halfTaps; k++ {
+			idx := center + k
+			if idx < 0 || idx >= len(samples) {
+				continue
+			}
+			x := srcPos - float64(idx)
+			w := sincWindowed(x, halfTaps)
+			sum += float64(samples[idx]) * w
+			weight += w
+		}
+		if weight == 0 {
+			out[i] = samples[clampIdx(center, len(samples))]
+			continue
+		}
+		v := sum / weight
+		out[i] = clampToInt16(v)
+	}
+	return out
+}
+
+func linearResample(samples []int16, outLen int, ratio float64) []int16 {
+	out := make([]int16, outLen)
+	for i := 0; i < outLen; i++ {
+		srcPos := float64(i) * ratio
+		idx := int(srcPos)
+		frac := srcPos - float64(idx)
+		if idx+1 >= len(samples) {
+			out[i] = samples[clampIdx(idx, len(samples))]
+			continue
+		}
+		s0, s1 := float64(samples[idx]), float64(samples[idx+1])
+		out[i] = clampToInt16(s0 + (s1-s0)*frac)
+	}
+	return out
+}
+
+// sincWindowed evaluates sinc(x) under a Hann window spanning
+// +/-halfTaps, which is the interpolation kernel for the "low"/"medium"/
+// "high" resampler quality settings (9/17/33 taps).
+func sincWindowed(x float64, halfTaps int) float64 {
+	if x == 0 {
+		return 1.0
+	}
+	sinc := math.Sin(math.Pi*x) / (math.Pi * x)
+	hann := 0.5 * (1 + math.Cos(math.Pi*x/float64(halfTaps)))
+	return sinc * hann
+}
+
+func clampIdx(i, n int) int {
+	if i < 0 {
+		return 0
+	}
+	if i >= n {
+		return n - 1
+	}
+	return i
+}
+
+func clampToInt16(v float64) int16 {
+	if v > 32767 {
+		return 32767
+	}
+	if v < -32768 {
+		return -32768
+	}
+	return int16(v)
+}
@@ -0,0 +1,71 @@
+package decoder
+
+import "testing"
+
+func TestResampleNoopWhenRatesMatch(t *testing.T) {
+	in := []int16{1, 2, 3, 4}
+	out := resample(in, 44100, 44100, QualityMedium)
+	if len(out) != len(in) {
+		t.Fatalf("expected passthrough of length %d, got %d", len(in), len(out))
+	}
+	for i := range in {
+		if out[i] != in[i] {
+			t.Fatalf("sample %d changed on no-op resample: got %d want %d", i, out[i], in[i])
+		}
+	}
+}
+
+func TestResampleOutputLengthMatchesRatio(t *testing.T) {
+	cases := []struct {
+		name                   string
+		sourceRate, targetRate int
+		in                     int
+	}{
+		{"downsample-48k-to-44k1", 48000, 44100, 4800},
+		{"upsample-22k-to-44k1", 22050, 44100, 2205},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			in := make([]int16, c.in)
+			for i := range in {
+				in[i] = int16(i % 100)
+			}
+			out := resample(in, c.sourceRate, c.targetRate, QualityMedium)
+			wantLen := int(float64(len(in)) / (float64(c.sourceRate) / float64(c.targetRate)))
+			if len(out) != wantLen {
+				t.Fatalf("got %d output samples, want %d", len(out), wantLen)
+			}
+		})
+	}
+}
+
+func TestResampleTinyClipFallsBackToLinear(t *testing.T) {
+	in := []int16{100, 200, 300}
+	out := resample(in, 48000, 44100, QualityHigh)
+	direct := linearResample(in, len(out), float64(48000)/float64(44100))
+	if len(out) != len(direct) {
+		t.Fatalf("got %d samples, want %d", len(out), len(direct))
+	}
+	for i := range out {
+		if out[i] != direct[i] {
+			t.Fatalf("sample %d = %d, want linear fallback %d", i, out[i], direct[i])
+		}
+	}
+}
+
+func TestClampToInt16(t *testing.T) {
+	cases := []struct {
+		in   float64
+		want int16
+	}{
+		{0, 0},
+		{40000, 32767},
+		{-40000, -32768},
+		{1234, 1234},
+	}
+	for _, c := range cases {
+		if got := clampToInt16(c.in); got != c.want {
+			t.Fatalf("clampToInt16(%v) = %d, want %d", c.in, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package decoder
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// decodeWAV parses a PCM WAV file (mono or stereo, 16-bit) into interleaved
+// int16 samples.
+func decodeWAV(path string) ([]int16, int, int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	r := bytes.NewReader(data)
+
+	var riffHeader [12]byte
+	if _, err := io.ReadFull(r, riffHeader[:]); err != nil {
+		return nil, 0, 0, fmt.Errorf("read riff header: %w", err)
+	}
+	if string(riffHeader[0:4]) != "RIFF" || string(riffHeader[8:12]) != "WAVE" {
+		return nil, 0, 0, fmt.Errorf("not a RIFF/WAVE file")
+	}
+
+	var channels, bitsPerSample, rate int
+	var samples []int16
+
+	for {
+		var chunkID [4]byte
+		var chunkSize uint32
+		if _, err := io.ReadFull(r, chunkID[:]); err != nil {
+			break
+		}
+		if err := binary.Read(r, binary.LittleEndian, &chunkSize); err != nil {
+			return nil, 0, 0, fmt.Errorf("read chunk size: %w", err)
+		}
+
+		switch string(chunkID[:]) {
+		case "fmt ":
+			var audioFormat, numChannels uint16
+			var sampleRateHz, byteRate uint32
+			var blockAlign, bits uint16
+			if err := binary.Read(r, binary.LittleEndian, &audioFormat); err != nil {
+				return nil, 0, 0, err
+			}
+			if err := binary.Read(r, binary.LittleEndian, &numChannels); err != nil {
+				return nil, 0, 0, err
+			}
+			if err := binary.Read(r, binary.LittleEndian, &sampleRateHz); err != nil {
+				return nil, 0, 0, err
+			}
+			if err := binary.Read(r, binary.LittleEndian, &byteRate); err != nil {
+				return nil, 0, 0, err
+			}
+			if err := binary.Read(r, binary.LittleEndian, &blockAlign); err != nil {
+				return nil, 0, 0, err
+			}
+			if err := binary.Read(r, binary.LittleEndian, &bits); err != nil {
+				return nil, 0, 0, err
+			}
+			channels = int(numChannels)
+			bitsPerSample = int(bits)
+			rate = int(sampleRateHz)
+			if remaining := int64(chunkSize) - 16; remaining > 0 {
+				if _, err := r.Seek(remaining, io.SeekCurrent); err != nil {
+					return nil, 0, 0, err
+				}
+			}
+		case "data":
+			if bitsPerSample != 16 {
+				return nil, 0, 0, fmt.Errorf("unsupported WAV bit depth: %d", bitsPerSample)
+			}
+			samples = make([]int16, chunkSize/2)
+			if err := binary.Read(r, binary.LittleEndian, samples); err != nil {
+				return nil, 0, 0, fmt.Errorf("read data chunk: %w", err)
+			}
+		default:
+			if _, err := r.Seek(int64(chunkSize), io.SeekCurrent); err != nil {
+				return nil, 0, 0, nil
+			}
+		}
+	}
+
+	if samples == nil {
+		return nil, 0, 0, fmt.Errorf("no data chunk found")
+	}
+	return samples, rate, channels, nil
+}
@@ -6,24 +6,42 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/yencarnacion/exit-indicator-aggr/tools/sound_preview/decoder"
+	"github.com/yencarnacion/exit-indicator-aggr/tools/sound_preview/midisink"
+	"github.com/yencarnacion/exit-indicator-aggr/tools/sound_preview/soundfont"
 )
 
 const sampleRate = 44100
 
 type note struct {
-	freqHz float64
-	startS float64
-	durS   float64
-	amp    float64
-	wave   string
+	freqHz   float64
+	startS   float64
+	durS     float64
+	amp      float64
+	wave     string
+	midiNote int // used instead of freqHz/wave when the sound has a preset
+	velocity int
+}
+
+// midiEvent is one note driven out to an external MIDI port/file when a
+// sound triggers under -midi-out or -export-smf.
+type midiEvent struct {
+	channel  int
+	midiNote int
+	velocity int
+	startS   float64
+	durS     float64
 }
 
 type soundDef struct {
@@ -33,6 +51,8 @@ type soundDef struct {
 	notes       []note
 	filePath    string
 	source      string
+	preset      *soundfont.Preset // when set, notes with midiNote render via the soundFont
+	midi        []midiEvent       // driven out under -midi-out (alongside audio unless -no-play) and -export-smf
 }
 
 type playerCmd struct {
@@ -72,7 +92,11 @@ func envelope(t, durS float64) float64 {
 	return math.Max(0.0, math.Exp(-6.9*x))
 }
 
-func synthesize(notes []note) ([]int16, error) {
+// synthesize renders s.notes into a float buffer and normalizes it to int16.
+// When s.preset is set, every note renders through font using its midiNote
+// (MIDI note 0 is valid) instead of the built-in oscillators.
+func synthesize(s soundDef, font *soundfont.Font) ([]int16, error) {
+	notes := s.notes
 	if len(notes) == 0 {
 		return nil, errors.New("no notes supplied")
 	}
@@ -90,6 +114,25 @@ func synthesize(notes []note) ([]int16, error) {
 
 	for _, n := range notes {
 		startI := int(n.startS * sampleRate)
+
+		if s.preset != nil {
+			if font == nil {
+				return nil, fmt.Errorf("sound %q references a preset but no -soundfont was loaded", s.key)
+			}
+			rendered, err := font.Render(*s.preset, n.midiNote, n.velocity, n.durS, sampleRate)
+			if err != nil {
+				return nil, fmt.Errorf("render preset note for %q: %w", s.key, err)
+			}
+			for j, v := range rendered {
+				idx := startI + j
+				if idx < 0 || idx >= frameCount {
+					break
+				}
+				buf[idx] += n.amp * v
+			}
+			continue
+		}
+
 		nFrames := int(n.durS * sampleRate)
 		omega := 2.0 * math.Pi * n.freqHz
 
@@ -129,7 +172,9 @@ func synthesize(notes []note) ([]int16, error) {
 	return out, nil
 }
 
-func writeWAV(path string, samples []int16) error {
+// writeWAVTo encodes samples as a mono 16-bit PCM WAV into w, e.g. a
+// bytes.Buffer for the HTTP server or a file for writeWAV.
+func writeWAVTo(w io.Writer, samples []int16) error {
 	const channels = 1
 	const bitsPerSample = 16
 	byteRate := sampleRate * channels * (bitsPerSample / 8)
@@ -177,6 +222,15 @@ func writeWAV(path string, samples []int16) error {
 		}
 	}
 
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+func writeWAV(path string, samples []int16) error {
+	var b bytes.Buffer
+	if err := writeWAVTo(&b, samples); err != nil {
+		return err
+	}
 	return os.WriteFile(path, b.Bytes(), 0o644)
 }
 
@@ -240,6 +294,16 @@ func playMP3(path string) error {
 	})
 }
 
+// playCompressed handles the formats whose only common system players are
+// ffplay/play (no single-purpose CLI exists for FLAC/Opus the way aplay and
+// mpg123 do for wav/mp3).
+func playCompressed(path string) error {
+	return runFirstAvailable([]playerCmd{
+		{name: "ffplay", args: []string{"-nodisp", "-autoexit", "-loglevel", "quiet", path}},
+		{name: "play", args: []string{"-q", path}},
+	})
+}
+
 func playAudioFile(path string) error {
 	ext := strings.ToLower(filepath.Ext(path))
 	switch ext {
@@ -247,6 +311,8 @@ func playAudioFile(path string) error {
 		return playWAV(path)
 	case ".mp3", ".mpeg":
 		return playMP3(path)
+	case ".flac", ".ogg", ".opus":
+		return playCompressed(path)
 	default:
 		return fmt.Errorf("unsupported audio file extension %q for %s", ext, path)
 	}
@@ -265,12 +331,7 @@ func playSamples(samples []int16) error {
 }
 
 func isSupportedAudioExt(ext string) bool {
-	switch strings.ToLower(ext) {
-	case ".wav", ".wave", ".mp3", ".mpeg":
-		return true
-	default:
-		return false
-	}
+	return decoder.IsSupportedExt(ext)
 }
 
 func discoverFileSounds(soundDir string) ([]soundDef, error) {
@@ -387,7 +448,7 @@ func buildSynthSounds() map[string]soundDef {
 			{freqHz: 880.00, startS: 0.00, durS: 0.15, amp: 0.10, wave: "sine"},
 		},
 	}
-	return map[string]soundDef{
+	sounds := map[string]soundDef{
 		askHit.key:            askHit,
 		bidHit.key:            bidHit,
 		marketCrossedUp.key:   marketCrossedUp,
@@ -396,6 +457,102 @@ func buildSynthSounds() map[string]soundDef {
 		rvolTickPace.key:      rvolTickPace,
 		alertFallback.key:     alertFallback,
 	}
+	for key, s := range sounds {
+		s.midi = deriveMIDIFromNotes(s.notes)
+		sounds[key] = s
+	}
+	return sounds
+}
+
+// deriveMIDIFromNotes builds a -midi-out/-export-smf fallback for a synth
+// sound that has no hand-authored midi field, so every built-in alert has a
+// MIDI representation without duplicating its note array.
+func deriveMIDIFromNotes(notes []note) []midiEvent {
+	events := make([]midiEvent, 0, len(notes))
+	for _, n := range notes {
+		events = append(events, midiEvent{
+			channel:  0,
+			midiNote: freqToMIDINote(n.freqHz),
+			velocity: ampToVelocity(n.amp),
+			startS:   n.startS,
+			durS:     n.durS,
+		})
+	}
+	return events
+}
+
+// toMIDISinkEvents adapts this package's midiEvent to midisink.Event.
+func toMIDISinkEvents(events []midiEvent) []midisink.Event {
+	out := make([]midisink.Event, len(events))
+	for i, e := range events {
+		out[i] = midisink.Event{
+			Channel:  e.channel,
+			Note:     e.midiNote,
+			Velocity: e.velocity,
+			StartS:   e.startS,
+			DurS:     e.durS,
+		}
+	}
+	return out
+}
+
+// freqToMIDINote rounds freqHz to the nearest MIDI note, using A4=69=440Hz.
+func freqToMIDINote(freqHz float64) int {
+	if freqHz <= 0 {
+		return 0
+	}
+	note := 69.0 + 12.0*math.Log2(freqHz/440.0)
+	rounded := int(math.Round(note))
+	if rounded < 0 {
+		return 0
+	}
+	if rounded > 127 {
+		return 127
+	}
+	return rounded
+}
+
+// ampToVelocity maps this tool's 0..~0.5 synth amplitudes onto 0-127 MIDI
+// velocity, since the oscillators are mixed well below clipping headroom.
+func ampToVelocity(amp float64) int {
+	v := int(math.Round(amp * 2.0 * 127.0))
+	if v < 1 {
+		return 1
+	}
+	if v > 127 {
+		return 127
+	}
+	return v
+}
+
+// parseSoundFontNote parses the -soundfont-note flag's
+// "bank:program:midiNote:velocity" form into a one-shot preset-backed
+// soundDef, so a specific SF2/SF3 preset is reachable without hand-wiring it
+// into the built-in catalog.
+func parseSoundFontNote(spec string) (soundDef, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) != 4 {
+		return soundDef{}, fmt.Errorf("invalid -soundfont-note %q, want bank:program:midiNote:velocity", spec)
+	}
+	vals := make([]int, 4)
+	for i, p := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			return soundDef{}, fmt.Errorf("invalid -soundfont-note %q: %w", spec, err)
+		}
+		vals[i] = v
+	}
+	bank, program, midiNote, velocity := vals[0], vals[1], vals[2], vals[3]
+	return soundDef{
+		key:         "soundfont-note",
+		title:       fmt.Sprintf("SoundFont preset %d:%d note %d", bank, program, midiNote),
+		description: "Ad hoc SF2/SF3 preset note requested via -soundfont-note.",
+		source:      "soundfont",
+		preset:      &soundfont.Preset{Bank: bank, Program: program},
+		notes: []note{
+			{startS: 0, durS: 1.0, amp: 0.5, midiNote: midiNote, velocity: velocity},
+		},
+	}, nil
 }
 
 func buildCatalog(soundDir string) (map[string]soundDef, []string, error) {
@@ -428,7 +585,14 @@ func main() {
 	listOnly := flag.Bool("list", false, "List available sounds and exit")
 	gapMs := flag.Int("gap-ms", 180, "Silence gap between sounds in milliseconds")
 	noPlay := flag.Bool("no-play", false, "Print descriptions but skip audio playback")
-	soundsDir := flag.String("sounds-dir", "web/sounds", "Directory to scan for .wav/.mp3 sound files")
+	soundsDir := flag.String("sounds-dir", "web/sounds", "Directory to scan for .wav/.mp3/.flac/.ogg/.opus sound files")
+	backend := flag.String("backend", "auto", "Playback backend: auto, portaudio, or exec")
+	soundFontPath := flag.String("soundfont", "", "Path to an SF2/SF3 file for preset-based sounds")
+	soundFontNote := flag.String("soundfont-note", "", "bank:program:midiNote:velocity to trigger via -soundfont, e.g. 0:0:69:100")
+	score := flag.String("score", "", "Composite score to layer, e.g. ask-hit@0,ask-hit@120,rvol-tick-pace@240")
+	midiOut := flag.String("midi-out", "", "Name of a MIDI output port to drive instead of (or alongside) audio")
+	exportSMF := flag.String("export-smf", "", "Write the sound catalog as a Standard MIDI File to this path and exit")
+	serveAddr := flag.String("serve", "", "Start an HTTP server (e.g. :8080) exposing the sound catalog instead of playing locally")
 	flag.Parse()
 
 	if *gapMs < 0 {
@@ -436,11 +600,35 @@ func main() {
 		os.Exit(2)
 	}
 
+	var font *soundfont.Font
+	if *soundFontPath != "" {
+		f, err := soundfont.Load(*soundFontPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load -soundfont %q: %v\n", *soundFontPath, err)
+			os.Exit(2)
+		}
+		font = f
+	}
+
 	sounds, defaultOrder, err := buildCatalog(*soundsDir)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to build sound catalog: %v\n", err)
 		os.Exit(2)
 	}
+	if *soundFontNote != "" {
+		if font == nil {
+			fmt.Fprintln(os.Stderr, "-soundfont-note requires -soundfont")
+			os.Exit(2)
+		}
+		sfSound, err := parseSoundFontNote(*soundFontNote)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(2)
+		}
+		sounds[sfSound.key] = sfSound
+		defaultOrder = append(defaultOrder, sfSound.key)
+	}
+
 	order := flag.Args()
 	if len(order) == 0 {
 		order = defaultOrder
@@ -458,6 +646,66 @@ func main() {
 		return
 	}
 
+	if *exportSMF != "" {
+		tracks := make([]midisink.Track, 0, len(defaultOrder))
+		for _, k := range defaultOrder {
+			s := sounds[k]
+			tracks = append(tracks, midisink.Track{Name: s.key, Events: toMIDISinkEvents(s.midi)})
+		}
+		if err := midisink.WriteSMF(*exportSMF, tracks); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to export -export-smf %q: %v\n", *exportSMF, err)
+			os.Exit(2)
+		}
+		fmt.Printf("Wrote %s\n", *exportSMF)
+		return
+	}
+
+	if *serveAddr != "" {
+		if err := serve(*serveAddr, sounds, defaultOrder, font); err != nil {
+			fmt.Fprintf(os.Stderr, "HTTP server failed: %v\n", err)
+			os.Exit(2)
+		}
+		return
+	}
+
+	var midiPort midisink.Port
+	if *midiOut != "" {
+		p, err := midisink.OpenPort(*midiOut)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open -midi-out %q: %v\n", *midiOut, err)
+			os.Exit(2)
+		}
+		midiPort = p
+		defer midiPort.Close()
+	}
+
+	player, err := newPlayer(*backend)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open audio backend: %v\n", err)
+		os.Exit(2)
+	}
+	defer player.Close()
+
+	if *score != "" {
+		mixer, ok := player.(*Mixer)
+		if !ok {
+			fmt.Fprintln(os.Stderr, "-score requires the portaudio backend")
+			os.Exit(2)
+		}
+		schedule, err := parseScore(*score)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -score: %v\n", err)
+			os.Exit(2)
+		}
+		if err := mixer.Schedule(schedule, sounds, font); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to schedule score: %v\n", err)
+			os.Exit(3)
+		}
+		time.Sleep(time.Duration(scoreDurationS(schedule, sounds)*1000) * time.Millisecond)
+		fmt.Println("Done.")
+		return
+	}
+
 	for _, key := range order {
 		s, ok := sounds[key]
 		if !ok {
@@ -471,19 +719,28 @@ func main() {
 			fmt.Printf("       file: %s\n", s.filePath)
 		}
 
+		if midiPort != nil {
+			midisink.Schedule(midiPort, toMIDISinkEvents(s.midi))
+		}
+
 		if !*noPlay {
-			if s.filePath != "" {
-				if err := playAudioFile(s.filePath); err != nil {
+			if mixer, ok := player.(*Mixer); ok {
+				if _, err := mixer.Trigger(s, font, TriggerOpts{Gain: 1.0}); err != nil {
+					fmt.Fprintf(os.Stderr, "Audio playback failed for %q: %v\n", s.key, err)
+					os.Exit(4)
+				}
+			} else if s.filePath != "" {
+				if err := player.PlayFile(s.filePath); err != nil {
 					fmt.Fprintf(os.Stderr, "Audio playback failed for %q: %v\n", s.key, err)
 					os.Exit(4)
 				}
 			} else {
-				samples, err := synthesize(s.notes)
+				samples, err := synthesize(s, font)
 				if err != nil {
 					fmt.Fprintf(os.Stderr, "Failed to synthesize %q: %v\n", s.key, err)
 					os.Exit(3)
 				}
-				if err := playSamples(samples); err != nil {
+				if err := player.Play(samples); err != nil {
 					fmt.Fprintf(os.Stderr, "Audio playback failed for %q: %v\n", s.key, err)
 					os.Exit(4)
 				}
@@ -492,5 +749,8 @@ func main() {
 		time.Sleep(time.Duration(*gapMs) * time.Millisecond)
 	}
 
+	if mixer, ok := player.(*Mixer); ok {
+		mixer.Drain()
+	}
 	fmt.Println("Done.")
 }
@@ -0,0 +1,45 @@
+// Package midisink drives alerts out to a MIDI port (ALSA/CoreMIDI/WinMM via
+// rtmidi) or bounces them to a Standard MIDI File, so a trading alert can
+// trigger an external synth or DAW instead of (or alongside) local audio.
+package midisink
+
+import "time"
+
+// Event is one scheduled note: Channel/Note/Velocity are 0-127 MIDI values,
+// StartS/DurS are seconds relative to when the containing sound triggers.
+type Event struct {
+	Channel  int
+	Note     int
+	Velocity int
+	StartS   float64
+	DurS     float64
+}
+
+// Port is an open MIDI output; Send writes one already-encoded message.
+type Port interface {
+	Send(msg []byte) error
+	Close() error
+}
+
+// Schedule fires Note-On/Note-Off messages for events against a monotonic
+// clock with millisecond accuracy, so a multi-note alert plays with the same
+// timing on the MIDI port as it would synthesized locally.
+func Schedule(port Port, events []Event) {
+	for _, e := range events {
+		e := e
+		time.AfterFunc(time.Duration(e.StartS*1000)*time.Millisecond, func() {
+			_ = port.Send(noteOn(e.Channel, e.Note, e.Velocity))
+		})
+		time.AfterFunc(time.Duration((e.StartS+e.DurS)*1000)*time.Millisecond, func() {
+			_ = port.Send(noteOff(e.Channel, e.Note))
+		})
+	}
+}
+
+func noteOn(channel, note, velocity int) []byte {
+	return []byte{byte(0x90 | (channel & 0x0F)), byte(note & 0x7F), byte(velocity & 0x7F)}
+}
+
+func noteOff(channel, note int) []byte {
+	return []byte{byte(0x80 | (channel & 0x0F)), byte(note & 0x7F), 0}
+}
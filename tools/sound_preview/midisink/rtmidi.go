@@ -0,0 +1,74 @@
+//go:build rtmidi
+
+package midisink
+
+import (
+	"fmt"
+
+	"gitlab.com/gomidi/midi/v2"
+	"gitlab.com/gomidi/midi/v2/drivers/rtmididrv"
+)
+
+type rtmidiPort struct {
+	drv *rtmididrv.Driver
+	out midi.Out
+}
+
+// OpenPort opens an output port by name (as returned by ListPorts).
+func OpenPort(name string) (Port, error) {
+	drv, err := rtmididrv.New()
+	if err != nil {
+		return nil, fmt.Errorf("open rtmidi driver: %w", err)
+	}
+
+	outs, err := drv.Outs()
+	if err != nil {
+		drv.Close()
+		return nil, fmt.Errorf("list midi outs: %w", err)
+	}
+	var out midi.Out
+	for _, o := range outs {
+		if o.String() == name {
+			out = o
+			break
+		}
+	}
+	if out == nil {
+		drv.Close()
+		return nil, fmt.Errorf("no midi output port named %q", name)
+	}
+	if err := out.Open(); err != nil {
+		drv.Close()
+		return nil, fmt.Errorf("open midi port %q: %w", name, err)
+	}
+	return &rtmidiPort{drv: drv, out: out}, nil
+}
+
+// ListPorts returns the names of available MIDI output ports.
+func ListPorts() ([]string, error) {
+	drv, err := rtmididrv.New()
+	if err != nil {
+		return nil, fmt.Errorf("open rtmidi driver: %w", err)
+	}
+	defer drv.Close()
+
+	outs, err := drv.Outs()
+	if err != nil {
+		return nil, fmt.Errorf("list midi outs: %w", err)
+	}
+	names := make([]string, len(outs))
+	for i, o := range outs {
+		names[i] = o.String()
+	}
+	return names, nil
+}
+
+func (p *rtmidiPort) Send(msg []byte) error {
+	return p.out.Send(msg)
+}
+
+func (p *rtmidiPort) Close() error {
+	err := p.out.Close()
+	p.drv.Close()
+	return err
+}
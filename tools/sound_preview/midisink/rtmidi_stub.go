@@ -0,0 +1,18 @@
+//go:build !rtmidi
+
+package midisink
+
+import "fmt"
+
+// OpenPort and ListPorts are unavailable in this build: rtmidi is a cgo
+// dependency (ALSA/CoreMIDI/WinMM), gated behind the "rtmidi" build tag so
+// the rest of this package builds and tests without those headers/libs
+// installed.
+
+func OpenPort(name string) (Port, error) {
+	return nil, fmt.Errorf("open midi port %q: built without rtmidi support (rebuild with -tags rtmidi)", name)
+}
+
+func ListPorts() ([]string, error) {
+	return nil, fmt.Errorf("list midi outs: built without rtmidi support (rebuild with -tags rtmidi)")
+}
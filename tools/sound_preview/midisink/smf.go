@@ -0,0 +1,93 @@
+package midisink
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"sort"
+)
+
+const ticksPerQuarter = 480
+
+// Track is one sound's worth of events to serialize as an MTrk chunk, named
+// so the exported file stays readable when opened in a DAW.
+type Track struct {
+	Name   string
+	Events []Event
+}
+
+// WriteSMF writes a Standard MIDI File (format 1) with one MTrk per track,
+// mirroring how writeWAV serializes one WAV per sound.
+func WriteSMF(path string, tracks []Track) error {
+	var buf bytes.Buffer
+
+	buf.WriteString("MThd")
+	_ = binary.Write(&buf, binary.BigEndian, uint32(6))
+	_ = binary.Write(&buf, binary.BigEndian, uint16(1)) // format 1
+	_ = binary.Write(&buf, binary.BigEndian, uint16(len(tracks)))
+	_ = binary.Write(&buf, binary.BigEndian, uint16(ticksPerQuarter))
+
+	for _, t := range tracks {
+		track := encodeTrack(t)
+		buf.WriteString("MTrk")
+		_ = binary.Write(&buf, binary.BigEndian, uint32(len(track)))
+		buf.Write(track)
+	}
+
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+type timedMessage struct {
+	tick int
+	msg  []byte
+}
+
+// encodeTrack lays out note-on/note-off pairs in tick order with
+// variable-length-quantity delta times, assuming a fixed 120bpm (500000us
+// per quarter) so StartS/DurS convert directly to ticks.
+func encodeTrack(t Track) []byte {
+	const usPerQuarter = 500000
+	secondsToTicks := func(s float64) int {
+		return int(s * 1e6 / usPerQuarter * ticksPerQuarter)
+	}
+
+	var events []timedMessage
+	for _, e := range t.Events {
+		events = append(events, timedMessage{tick: secondsToTicks(e.StartS), msg: noteOn(e.Channel, e.Note, e.Velocity)})
+		events = append(events, timedMessage{tick: secondsToTicks(e.StartS + e.DurS), msg: noteOff(e.Channel, e.Note)})
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].tick < events[j].tick })
+
+	var buf bytes.Buffer
+	buf.Write(vlq(0))
+	buf.Write([]byte{0xFF, 0x03}) // track name meta event
+	buf.Write(vlq(len(t.Name)))
+	buf.WriteString(t.Name)
+
+	last := 0
+	for _, ev := range events {
+		buf.Write(vlq(ev.tick - last))
+		buf.Write(ev.msg)
+		last = ev.tick
+	}
+
+	buf.Write(vlq(0))
+	buf.Write([]byte{0xFF, 0x2F, 0x00}) // end of track
+	return buf.Bytes()
+}
+
+// vlq encodes n as a MIDI variable-length quantity.
+func vlq(n int) []byte {
+	if n == 0 {
+		return []byte{0x00}
+	}
+	var bs []byte
+	for n > 0 {
+		bs = append([]byte{byte(n & 0x7F)}, bs...)
+		n >>= 7
+	}
+	for i := 0; i < len(bs)-1; i++ {
+		bs[i] |= 0x80
+	}
+	return bs
+}
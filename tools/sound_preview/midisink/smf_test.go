@@ -0,0 +1,74 @@
+package midisink
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestVLQ(t *testing.T) {
+	cases := []struct {
+		n    int
+		want []byte
+	}{
+		{0, []byte{0x00}},
+		{0x40, []byte{0x40}},
+		{0x7F, []byte{0x7F}},
+		{0x80, []byte{0x81, 0x00}},
+		{0x2000, []byte{0xC0, 0x00}},
+		{0x3FFF, []byte{0xFF, 0x7F}},
+	}
+	for _, c := range cases {
+		if got := vlq(c.n); !bytes.Equal(got, c.want) {
+			t.Fatalf("vlq(%#x) = % X, want % X", c.n, got, c.want)
+		}
+	}
+}
+
+func TestNoteOnOff(t *testing.T) {
+	if got, want := noteOn(0, 69, 100), []byte{0x90, 69, 100}; !bytes.Equal(got, want) {
+		t.Fatalf("noteOn(0,69,100) = % X, want % X", got, want)
+	}
+	if got, want := noteOn(2, 69, 100), []byte{0x92, 69, 100}; !bytes.Equal(got, want) {
+		t.Fatalf("noteOn(2,69,100) = % X, want % X", got, want)
+	}
+	if got, want := noteOff(0, 69), []byte{0x80, 69, 0}; !bytes.Equal(got, want) {
+		t.Fatalf("noteOff(0,69) = % X, want % X", got, want)
+	}
+}
+
+// TestEncodeTrackTickOrder checks that encodeTrack's 120bpm seconds-to-ticks
+// conversion lands two quarter notes (2*ticksPerQuarter ticks) per second,
+// and that overlapping events still come out in tick order.
+func TestEncodeTrackTickOrder(t *testing.T) {
+	track := Track{
+		Name: "t",
+		Events: []Event{
+			{Channel: 0, Note: 60, Velocity: 100, StartS: 1.0, DurS: 0.5},
+			{Channel: 0, Note: 64, Velocity: 100, StartS: 0.0, DurS: 2.0},
+		},
+	}
+	data := encodeTrack(track)
+
+	// 4 delta+event pairs (2 note-ons, 2 note-offs) plus the name and
+	// end-of-track meta events must all be present in the byte stream.
+	// Note 64 on at tick 0, note 60 on 960 ticks later (1s), note 60 off
+	// 480 ticks after that (1.5s), note 64 off another 480 ticks later (2s).
+	wantEvents := []byte{
+		0x00, 0x90, 64, 100,
+		0x87, 0x40, 0x90, 60, 100,
+		0x83, 0x60, 0x80, 60, 0,
+		0x83, 0x60, 0x80, 64, 0,
+	}
+	if !bytes.Contains(data, wantEvents) {
+		t.Fatalf("encodeTrack note events out of tick order or mistimed, got % X", data)
+	}
+	if !bytes.Contains(data, []byte{0xFF, 0x03}) {
+		t.Fatalf("encodeTrack output missing track name meta event")
+	}
+	if !bytes.Contains(data, []byte{0xFF, 0x2F, 0x00}) {
+		t.Fatalf("encodeTrack output missing end-of-track meta event")
+	}
+	if !bytes.HasSuffix(data, []byte{0x00, 0xFF, 0x2F, 0x00}) {
+		t.Fatalf("end-of-track event must be last, got suffix % X", data[len(data)-4:])
+	}
+}
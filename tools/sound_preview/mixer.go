@@ -0,0 +1,297 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gordonklaus/portaudio"
+
+	"github.com/yencarnacion/exit-indicator-aggr/tools/sound_preview/soundfont"
+)
+
+const (
+	ringJitterMs         = 20
+	ringFrameCap         = sampleRate * ringJitterMs / 1000
+	defaultVoiceCap      = 16
+	defaultDedupWindowMs = 60
+)
+
+// TriggerOpts controls how a single Mixer.Trigger call is mixed in. There's
+// no Pan here: the output device is hardcoded mono (playerChannels), so a
+// pan value would have nothing to do.
+type TriggerOpts struct {
+	Gain     float64 // linear gain multiplier, 1.0 = unity
+	Priority int     // higher survives voice stealing longer
+	Preempt  bool    // stop any voice with the same sound key before starting
+}
+
+// ScheduledTrigger is one entry in a Mixer.Schedule score: play SoundKey
+// OffsetMs after the score starts, mixed in at Gain.
+type ScheduledTrigger struct {
+	SoundKey string
+	OffsetMs int
+	Gain     float64
+}
+
+// voice is one active playback stream inside the mixer.
+type voice struct {
+	key      string
+	priority int
+	gain     float64
+	samples  []int16
+	pos      int
+	started  time.Time
+	stopped  atomic.Bool
+}
+
+// VoiceHandle lets a caller stop a triggered voice before it finishes.
+type VoiceHandle struct {
+	v *voice
+}
+
+// Stop preempts this voice on the next callback tick.
+func (h VoiceHandle) Stop() {
+	if h.v != nil {
+		h.v.stopped.Store(true)
+	}
+}
+
+// Mixer owns the PortAudio output device and mixes a capped set of active
+// voices every callback block, so a burst of alerts can overlap instead of
+// serializing through a single playSamples call.
+type Mixer struct {
+	stream *portaudio.Stream
+	cap    int
+	dedup  time.Duration
+
+	mu        sync.Mutex
+	voices    []*voice
+	lastByKey map[string]time.Time
+}
+
+func newMixer(voiceCap int, dedupWindowMs int) (*Mixer, error) {
+	if err := portaudio.Initialize(); err != nil {
+		return nil, fmt.Errorf("portaudio init: %w", err)
+	}
+
+	m := &Mixer{
+		cap:       voiceCap,
+		dedup:     time.Duration(dedupWindowMs) * time.Millisecond,
+		lastByKey: make(map[string]time.Time),
+	}
+
+	stream, err := portaudio.OpenDefaultStream(0, playerChannels, float64(sampleRate), ringFrameCap, m.callback)
+	if err != nil {
+		portaudio.Terminate()
+		return nil, fmt.Errorf("portaudio open stream: %w", err)
+	}
+	if err := stream.Start(); err != nil {
+		stream.Close()
+		portaudio.Terminate()
+		return nil, fmt.Errorf("portaudio start stream: %w", err)
+	}
+	m.stream = stream
+	return m, nil
+}
+
+// callback runs on the PortAudio audio thread; it must not block or allocate
+// more than necessary.
+func (m *Mixer) callback(out []int16) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range out {
+		out[i] = 0
+	}
+
+	live := m.voices[:0]
+	for _, v := range m.voices {
+		if v.stopped.Load() || v.pos >= len(v.samples) {
+			continue
+		}
+		for i := range out {
+			if v.pos >= len(v.samples) {
+				break
+			}
+			mixed := int32(out[i]) + int32(float64(v.samples[v.pos])*v.gain)
+			out[i] = clampInt16(mixed)
+			v.pos++
+		}
+		if v.pos < len(v.samples) {
+			live = append(live, v)
+		}
+	}
+	m.voices = live
+}
+
+func clampInt16(v int32) int16 {
+	if v > 32767 {
+		return 32767
+	}
+	if v < -32768 {
+		return -32768
+	}
+	return int16(v)
+}
+
+// Drain blocks until every active voice has finished playing, so a caller
+// about to Close the mixer doesn't cut off whatever was triggered last.
+func (m *Mixer) Drain() {
+	for {
+		m.mu.Lock()
+		n := len(m.voices)
+		m.mu.Unlock()
+		if n == 0 {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// addVoice applies the voice cap (stealing the lowest-priority, then oldest,
+// voice) and returns the handle for the newly added voice.
+func (m *Mixer) addVoice(v *voice) VoiceHandle {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.voices) >= m.cap {
+		stealIdx := 0
+		for i, existing := range m.voices {
+			if existing.priority < m.voices[stealIdx].priority ||
+				(existing.priority == m.voices[stealIdx].priority && existing.started.Before(m.voices[stealIdx].started)) {
+				stealIdx = i
+			}
+		}
+		m.voices[stealIdx].stopped.Store(true)
+		m.voices = append(m.voices[:stealIdx], m.voices[stealIdx+1:]...)
+	}
+	m.voices = append(m.voices, v)
+	return VoiceHandle{v: v}
+}
+
+// Trigger mixes in samples/file sounds for s, honoring per-key de-duplication
+// so rapid repeated triggers (e.g. RVOL ticks) don't pile up into distortion.
+func (m *Mixer) Trigger(s soundDef, font *soundfont.Font, opts TriggerOpts) (VoiceHandle, error) {
+	now := time.Now()
+	m.mu.Lock()
+	if last, ok := m.lastByKey[s.key]; ok && now.Sub(last) < m.dedup {
+		m.mu.Unlock()
+		return VoiceHandle{}, nil
+	}
+	m.lastByKey[s.key] = now
+	if opts.Preempt {
+		for _, v := range m.voices {
+			if v.key == s.key {
+				v.stopped.Store(true)
+			}
+		}
+	}
+	m.mu.Unlock()
+
+	var samples []int16
+	var err error
+	if s.filePath != "" {
+		samples, _, _, err = decodeAudioFile(s.filePath)
+	} else {
+		samples, err = synthesize(s, font)
+	}
+	if err != nil {
+		return VoiceHandle{}, fmt.Errorf("trigger %q: %w", s.key, err)
+	}
+
+	gain := opts.Gain
+	if gain == 0 {
+		gain = 1.0
+	}
+	v := &voice{key: s.key, priority: opts.Priority, gain: gain, samples: samples, started: now}
+	return m.addVoice(v), nil
+}
+
+// Schedule lays out a score of smaller primitives against a monotonic clock,
+// e.g. ask-hit@0,ask-hit@120,rvol-tick-pace@240, so composite alerts can be
+// authored as a sequence of sounds instead of one hardcoded note array.
+func (m *Mixer) Schedule(score []ScheduledTrigger, sounds map[string]soundDef, font *soundfont.Font) error {
+	for _, st := range score {
+		s, ok := sounds[st.SoundKey]
+		if !ok {
+			return fmt.Errorf("schedule: unknown sound key %q", st.SoundKey)
+		}
+		st := st
+		time.AfterFunc(time.Duration(st.OffsetMs)*time.Millisecond, func() {
+			_, _ = m.Trigger(s, font, TriggerOpts{Gain: st.Gain})
+		})
+	}
+	return nil
+}
+
+// Play implements Player by triggering an ad hoc synth-less voice directly
+// from already-rendered samples, skipping soundDef/key bookkeeping.
+func (m *Mixer) Play(samples []int16) error {
+	_ = m.addVoice(&voice{key: "", priority: 0, gain: 1.0, samples: samples, started: time.Now()})
+	return nil
+}
+
+func (m *Mixer) PlayFile(path string) error {
+	samples, _, _, err := decodeAudioFile(path)
+	if err != nil {
+		return fmt.Errorf("decode %q: %w", path, err)
+	}
+	return m.Play(samples)
+}
+
+// parseScore parses the -score flag's "key@offsetMs,key@offsetMs,..." form
+// into a schedule, defaulting each entry's gain to unity.
+func parseScore(spec string) ([]ScheduledTrigger, error) {
+	var out []ScheduledTrigger
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		at := strings.LastIndex(part, "@")
+		if at < 0 {
+			return nil, fmt.Errorf("invalid score entry %q, want key@offsetMs", part)
+		}
+		offsetMs, err := strconv.Atoi(part[at+1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid offset in score entry %q: %w", part, err)
+		}
+		out = append(out, ScheduledTrigger{SoundKey: part[:at], OffsetMs: offsetMs, Gain: 1.0})
+	}
+	return out, nil
+}
+
+// scoreDurationS estimates how long a score takes to finish playing, so the
+// caller knows how long to keep the process (and its audio device) alive.
+func scoreDurationS(score []ScheduledTrigger, sounds map[string]soundDef) float64 {
+	end := 0.0
+	for _, st := range score {
+		s, ok := sounds[st.SoundKey]
+		if !ok {
+			continue
+		}
+		dur := 0.0
+		for _, n := range s.notes {
+			if e := n.startS + n.durS; e > dur {
+				dur = e
+			}
+		}
+		if e := float64(st.OffsetMs)/1000.0 + dur; e > end {
+			end = e
+		}
+	}
+	return end
+}
+
+func (m *Mixer) Close() error {
+	if m.stream == nil {
+		return nil
+	}
+	err := m.stream.Close()
+	portaudio.Terminate()
+	return err
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// newTestMixer builds a Mixer without opening a PortAudio stream, so the
+// voice-cap/dedup/scheduling logic can be exercised without real audio
+// hardware.
+func newTestMixer(voiceCap int, dedupWindowMs int) *Mixer {
+	return &Mixer{
+		cap:       voiceCap,
+		dedup:     time.Duration(dedupWindowMs) * time.Millisecond,
+		lastByKey: make(map[string]time.Time),
+	}
+}
+
+func TestAddVoiceStealsLowestPriority(t *testing.T) {
+	m := newTestMixer(2, 0)
+	now := time.Now()
+
+	low := &voice{key: "low", priority: 0, samples: make([]int16, 10), started: now}
+	high := &voice{key: "high", priority: 5, samples: make([]int16, 10), started: now}
+	m.addVoice(low)
+	m.addVoice(high)
+
+	newer := &voice{key: "newer", priority: 1, samples: make([]int16, 10), started: now.Add(time.Millisecond)}
+	m.addVoice(newer)
+
+	if len(m.voices) != 2 {
+		t.Fatalf("expected voice cap of 2 to hold, got %d voices", len(m.voices))
+	}
+	if !low.stopped.Load() {
+		t.Fatalf("lowest-priority voice should have been stolen")
+	}
+	if high.stopped.Load() {
+		t.Fatalf("higher-priority voice should have survived")
+	}
+}
+
+func TestAddVoiceStealsOldestOnPriorityTie(t *testing.T) {
+	m := newTestMixer(1, 0)
+	now := time.Now()
+
+	older := &voice{key: "older", priority: 0, samples: make([]int16, 10), started: now}
+	m.addVoice(older)
+
+	newer := &voice{key: "newer", priority: 0, samples: make([]int16, 10), started: now.Add(time.Millisecond)}
+	m.addVoice(newer)
+
+	if !older.stopped.Load() {
+		t.Fatalf("older same-priority voice should have been stolen")
+	}
+	if len(m.voices) != 1 || m.voices[0] != newer {
+		t.Fatalf("expected only the newer voice to remain")
+	}
+}
+
+func TestTriggerDedupWindow(t *testing.T) {
+	m := newTestMixer(8, 1000)
+	sd := soundDef{key: "ask-hit", notes: []note{{freqHz: 440, startS: 0, durS: 0.01, amp: 0.1, wave: "sine"}}}
+
+	if _, err := m.Trigger(sd, nil, TriggerOpts{}); err != nil {
+		t.Fatalf("first Trigger failed: %v", err)
+	}
+	if len(m.voices) != 1 {
+		t.Fatalf("expected 1 voice after first trigger, got %d", len(m.voices))
+	}
+
+	if _, err := m.Trigger(sd, nil, TriggerOpts{}); err != nil {
+		t.Fatalf("second Trigger failed: %v", err)
+	}
+	if len(m.voices) != 1 {
+		t.Fatalf("expected dedup window to suppress the second trigger, still got %d voices", len(m.voices))
+	}
+}
+
+func TestParseScore(t *testing.T) {
+	got, err := parseScore("ask-hit@0,bid-hit@120")
+	if err != nil {
+		t.Fatalf("parseScore returned error: %v", err)
+	}
+	want := []ScheduledTrigger{
+		{SoundKey: "ask-hit", OffsetMs: 0, Gain: 1.0},
+		{SoundKey: "bid-hit", OffsetMs: 120, Gain: 1.0},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("parseScore returned %d entries, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if _, err := parseScore("missing-offset"); err == nil {
+		t.Fatalf("parseScore should reject an entry without @offsetMs")
+	}
+}
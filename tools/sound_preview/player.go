@@ -0,0 +1,56 @@
+package main
+
+const playerChannels = 1
+
+// Player abstracts alert playback so the caller doesn't need to know whether
+// audio is rendered in-process via PortAudio or handed off to a system player.
+type Player interface {
+	Play(samples []int16) error
+	PlayFile(path string) error
+	Close() error
+}
+
+// newPlayer opens the requested backend. "auto" tries the Mixer's PortAudio
+// device first and falls back to the exec-based backend if it can't be
+// opened; "portaudio" and "exec" force a specific backend.
+func newPlayer(backend string) (Player, error) {
+	switch backend {
+	case "exec":
+		return newExecPlayer(), nil
+	case "portaudio":
+		return newMixer(defaultVoiceCap, defaultDedupWindowMs)
+	default:
+		m, err := newMixer(defaultVoiceCap, defaultDedupWindowMs)
+		if err == nil {
+			return m, nil
+		}
+		return newExecPlayer(), nil
+	}
+}
+
+// execPlayer shells out to a system player, as the tool always has. It plays
+// one sound at a time, so a burst of triggers serializes audibly.
+type execPlayer struct{}
+
+func newExecPlayer() *execPlayer {
+	return &execPlayer{}
+}
+
+func (p *execPlayer) Play(samples []int16) error {
+	return playSamples(samples)
+}
+
+// PlayFile decodes in-process, like the Mixer, and plays the result through
+// playSamples; a file the decoder rejects is handed to a system player via
+// playAudioFile instead.
+func (p *execPlayer) PlayFile(path string) error {
+	samples, _, _, err := decodeAudioFile(path)
+	if err != nil {
+		return playAudioFile(path)
+	}
+	return playSamples(samples)
+}
+
+func (p *execPlayer) Close() error {
+	return nil
+}
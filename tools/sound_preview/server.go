@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/yencarnacion/exit-indicator-aggr/tools/sound_preview/soundfont"
+)
+
+const defaultWAVCacheBytes = 64 * 1024 * 1024
+
+// soundMeta is the JSON shape served by GET /sounds, mirroring soundDef
+// without exposing internal fields like notes/midi.
+type soundMeta struct {
+	Key         string `json:"key"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Source      string `json:"source"`
+	HasFile     bool   `json:"hasFile"`
+}
+
+// wavCache is a byte-bounded LRU of rendered WAV bytes, so /sounds/{key}.wav
+// re-synthesizes a sound only after it has been evicted.
+type wavCache struct {
+	mu       sync.Mutex
+	maxBytes int
+	curBytes int
+	order    []string
+	data     map[string][]byte
+}
+
+func newWAVCache(maxBytes int) *wavCache {
+	return &wavCache{maxBytes: maxBytes, data: make(map[string][]byte)}
+}
+
+func (c *wavCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b, ok := c.data[key]
+	if ok {
+		c.touch(key)
+	}
+	return b, ok
+}
+
+// touch bumps key to the most-recently-used end of order so eviction in put
+// actually evicts the least-recently-used entry instead of the oldest
+// inserted one. Callers must hold c.mu.
+func (c *wavCache) touch(key string) {
+	for i, k := range c.order {
+		if k == key {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+	c.order = append(c.order, key)
+}
+
+func (c *wavCache) put(key string, b []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.data[key]; exists {
+		return
+	}
+	for c.curBytes+len(b) > c.maxBytes && len(c.order) > 0 {
+		oldest := c.order[0]
+		c.order = c.order[1:]
+		c.curBytes -= len(c.data[oldest])
+		delete(c.data, oldest)
+	}
+	c.data[key] = b
+	c.order = append(c.order, key)
+	c.curBytes += len(b)
+}
+
+// soundServer exposes the sound catalog over HTTP: GET /sounds lists it,
+// GET /sounds/{key}.wav synthesizes (and caches) on first hit, GET
+// /sounds/{key}.mp3 passes through file sounds whose source is already MP3,
+// and GET /stream broadcasts trigger events over a WebSocket.
+type soundServer struct {
+	sounds map[string]soundDef
+	order  []string
+	font   *soundfont.Font
+	cache  *wavCache
+
+	upgrader websocket.Upgrader
+
+	mu      sync.Mutex
+	clients map[*websocket.Conn]bool
+}
+
+func newSoundServer(sounds map[string]soundDef, order []string, font *soundfont.Font) *soundServer {
+	return &soundServer{
+		sounds:  sounds,
+		order:   order,
+		font:    font,
+		cache:   newWAVCache(defaultWAVCacheBytes),
+		clients: make(map[*websocket.Conn]bool),
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+func (s *soundServer) handleList(w http.ResponseWriter, r *http.Request) {
+	metas := make([]soundMeta, 0, len(s.order))
+	for _, k := range s.order {
+		sd := s.sounds[k]
+		metas = append(metas, soundMeta{
+			Key:         sd.key,
+			Title:       sd.title,
+			Description: sd.description,
+			Source:      sd.source,
+			HasFile:     sd.filePath != "",
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(struct {
+		Sounds []soundMeta `json:"sounds"`
+		Order  []string    `json:"order"`
+	}{Sounds: metas, Order: s.order})
+}
+
+func (s *soundServer) handleWAV(w http.ResponseWriter, r *http.Request, key string) {
+	sd, ok := s.sounds[key]
+	if !ok || sd.filePath != "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	etag := fmt.Sprintf(`"%x"`, hashNotes(sd.notes))
+	if match := r.Header.Get("If-None-Match"); match == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	cacheKey := key + ".wav"
+	data, ok := s.cache.get(cacheKey)
+	if !ok {
+		samples, err := synthesize(sd, s.font)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var buf bytes.Buffer
+		if err := writeWAVTo(&buf, samples); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		data = buf.Bytes()
+		s.cache.put(cacheKey, data)
+	}
+
+	w.Header().Set("Content-Type", "audio/wav")
+	w.Header().Set("ETag", etag)
+	http.ServeContent(w, r, key+".wav", time.Time{}, bytes.NewReader(data))
+}
+
+func (s *soundServer) handleMP3(w http.ResponseWriter, r *http.Request, key string) {
+	sd, ok := s.sounds[key]
+	if !ok || sd.filePath == "" || !strings.HasSuffix(strings.ToLower(sd.filePath), ".mp3") {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "audio/mpeg")
+	http.ServeFile(w, r, sd.filePath)
+}
+
+func (s *soundServer) handleSounds(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/sounds/")
+	if path == "" || path == r.URL.Path {
+		s.handleList(w, r)
+		return
+	}
+	switch {
+	case strings.HasSuffix(path, ".wav"):
+		s.handleWAV(w, r, strings.TrimSuffix(path, ".wav"))
+	case strings.HasSuffix(path, ".mp3"):
+		s.handleMP3(w, r, strings.TrimSuffix(path, ".mp3"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// streamTrigger is the JSON message a browser client sends over /stream; the
+// server broadcasts it to every connected client and plays nothing itself.
+type streamTrigger struct {
+	Key  string  `json:"key"`
+	Gain float64 `json:"gain"`
+}
+
+func (s *soundServer) handleStream(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("stream upgrade failed: %v", err)
+		return
+	}
+	s.mu.Lock()
+	s.clients[conn] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	for {
+		var trig streamTrigger
+		if err := conn.ReadJSON(&trig); err != nil {
+			return
+		}
+		if _, ok := s.sounds[trig.Key]; !ok {
+			continue
+		}
+		s.broadcast(trig)
+	}
+}
+
+func (s *soundServer) broadcast(trig streamTrigger) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.clients {
+		if err := c.WriteJSON(trig); err != nil {
+			c.Close()
+			delete(s.clients, c)
+		}
+	}
+}
+
+// serve starts the HTTP server described in the -serve flag and blocks
+// until it exits (normally never, since ListenAndServe only returns on
+// error).
+func serve(addr string, sounds map[string]soundDef, order []string, font *soundfont.Font) error {
+	s := newSoundServer(sounds, order, font)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sounds", s.handleList)
+	mux.HandleFunc("/sounds/", s.handleSounds)
+	mux.HandleFunc("/stream", s.handleStream)
+	log.Printf("serving sound catalog on %s", addr)
+	return http.ListenAndServe(addr, mux)
+}
+
+func hashNotes(notes []note) uint64 {
+	h := fnv.New64a()
+	for _, n := range notes {
+		fmt.Fprintf(h, "%f|%f|%f|%f|%s;", n.freqHz, n.startS, n.durS, n.amp, n.wave)
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,295 @@
+package soundfont
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type rawBag struct {
+	genNdx uint16
+}
+
+type rawGen struct {
+	oper   uint16
+	amount int16
+	loByte byte
+	hiByte byte
+}
+
+// parsePdta walks the "pdta" LIST's subchunks (phdr/pbag/pgen/inst/ibag/igen/
+// shdr) and resolves presets -> instruments -> samples. Modulator chunks
+// (pmod/imod) are read but not applied; none of this tool's presets need
+// them yet.
+func (f *Font) parsePdta(data []byte) error {
+	chunks := map[string][]byte{}
+	r := bytes.NewReader(data)
+	for r.Len() > 0 {
+		id, size, err := readChunkHeader(r)
+		if err != nil {
+			break
+		}
+		body := make([]byte, size)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return err
+		}
+		if size%2 == 1 {
+			r.Seek(1, io.SeekCurrent)
+		}
+		chunks[id] = body
+	}
+
+	shdrs, err := parseShdr(chunks["shdr"])
+	if err != nil {
+		return fmt.Errorf("shdr: %w", err)
+	}
+	f.samples = shdrs
+
+	igen, err := parseGen(chunks["igen"])
+	if err != nil {
+		return fmt.Errorf("igen: %w", err)
+	}
+	ibag, err := parseBag(chunks["ibag"])
+	if err != nil {
+		return fmt.Errorf("ibag: %w", err)
+	}
+	instNames, instBagStart, err := parseInstHeaders(chunks["inst"])
+	if err != nil {
+		return fmt.Errorf("inst: %w", err)
+	}
+	f.instrs = buildZonesGeneric(instNames, instBagStart, ibag, igen, true)
+
+	pgen, err := parseGen(chunks["pgen"])
+	if err != nil {
+		return fmt.Errorf("pgen: %w", err)
+	}
+	pbag, err := parseBag(chunks["pbag"])
+	if err != nil {
+		return fmt.Errorf("pbag: %w", err)
+	}
+	presetHdrs, err := parsePhdr(chunks["phdr"])
+	if err != nil {
+		return fmt.Errorf("phdr: %w", err)
+	}
+	f.presets = buildPresets(presetHdrs, pbag, pgen)
+	return nil
+}
+
+func parseShdr(b []byte) ([]sampleHeader, error) {
+	const recSize = 46
+	n := len(b) / recSize
+	out := make([]sampleHeader, 0, n)
+	for i := 0; i < n; i++ {
+		rec := b[i*recSize : (i+1)*recSize]
+		name := strings.TrimRight(string(rec[0:20]), "\x00")
+		if name == "EOS" {
+			break
+		}
+		out = append(out, sampleHeader{
+			name:       name,
+			start:      binary.LittleEndian.Uint32(rec[20:24]),
+			end:        binary.LittleEndian.Uint32(rec[24:28]),
+			startLoop:  binary.LittleEndian.Uint32(rec[28:32]),
+			endLoop:    binary.LittleEndian.Uint32(rec[32:36]),
+			sampleRate: binary.LittleEndian.Uint32(rec[36:40]),
+			rootKey:    rec[40],
+			pitchCorr:  int8(rec[41]),
+			sampleType: binary.LittleEndian.Uint16(rec[44:46]),
+		})
+	}
+	return out, nil
+}
+
+func parseBag(b []byte) ([]rawBag, error) {
+	const recSize = 4
+	n := len(b) / recSize
+	out := make([]rawBag, n)
+	for i := 0; i < n; i++ {
+		out[i] = rawBag{genNdx: binary.LittleEndian.Uint16(b[i*recSize : i*recSize+2])}
+	}
+	return out, nil
+}
+
+func parseGen(b []byte) ([]rawGen, error) {
+	const recSize = 4
+	n := len(b) / recSize
+	out := make([]rawGen, n)
+	for i := 0; i < n; i++ {
+		rec := b[i*recSize : (i+1)*recSize]
+		out[i] = rawGen{
+			oper:   binary.LittleEndian.Uint16(rec[0:2]),
+			amount: int16(binary.LittleEndian.Uint16(rec[2:4])),
+			loByte: rec[2],
+			hiByte: rec[3],
+		}
+	}
+	return out, nil
+}
+
+func parseInstHeaders(b []byte) ([]string, []int, error) {
+	const recSize = 22
+	n := len(b) / recSize
+	names := make([]string, 0, n)
+	bagStart := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		rec := b[i*recSize : (i+1)*recSize]
+		name := strings.TrimRight(string(rec[0:20]), "\x00")
+		if name == "EOI" {
+			break
+		}
+		names = append(names, name)
+		bagStart = append(bagStart, int(binary.LittleEndian.Uint16(rec[20:22])))
+	}
+	return names, bagStart, nil
+}
+
+type rawPhdr struct {
+	name     string
+	program  int
+	bank     int
+	bagStart int
+}
+
+func parsePhdr(b []byte) ([]rawPhdr, error) {
+	const recSize = 38
+	n := len(b) / recSize
+	out := make([]rawPhdr, 0, n)
+	for i := 0; i < n; i++ {
+		rec := b[i*recSize : (i+1)*recSize]
+		name := strings.TrimRight(string(rec[0:20]), "\x00")
+		if name == "EOP" {
+			break
+		}
+		out = append(out, rawPhdr{
+			name:     name,
+			program:  int(binary.LittleEndian.Uint16(rec[20:22])),
+			bank:     int(binary.LittleEndian.Uint16(rec[22:24])),
+			bagStart: int(binary.LittleEndian.Uint16(rec[24:26])),
+		})
+	}
+	return out, nil
+}
+
+// applyGen folds one generator into a generators struct. Key/vel ranges
+// (genOperKeyRange/genOperVelRange) are not handled here; the zone builders
+// read them before calling applyGen.
+func applyGen(g *generators, gen rawGen) {
+	switch gen.oper {
+	case genOperSampleID:
+		g.sampleID = int(gen.amount)
+	case genOperOverrRoot:
+		g.overridingRoot = int(gen.amount)
+	case genOperFineTune:
+		g.fineTuneCents = int(gen.amount)
+	case genOperCoarseTune:
+		g.coarseTuneSemis = int(gen.amount)
+	case genOperAttenuate:
+		g.attenuationCb = int(gen.amount)
+	case genOperVolAttack:
+		g.volAttackS = timecentsToSeconds(gen.amount)
+	case genOperVolHold:
+		g.volHoldS = timecentsToSeconds(gen.amount)
+	case genOperVolDecay:
+		g.volDecayS = timecentsToSeconds(gen.amount)
+	case genOperVolSustain:
+		// Sustain is attenuation in centibels below peak; convert to a
+		// 0..1 fraction of peak amplitude remaining.
+		db := float64(gen.amount) / 10.0
+		g.volSustainFrac = dbToFrac(db)
+	case genOperVolRelease:
+		g.volReleaseS = timecentsToSeconds(gen.amount)
+	case genOperSampleMode:
+		g.loopMode = int(gen.amount)
+	}
+}
+
+func timecentsToSeconds(tc int16) float64 {
+	if tc <= -12000 {
+		return 0
+	}
+	// SF2 timecents: seconds = 2^(tc/1200).
+	return pow2(float64(tc) / 1200.0)
+}
+
+func dbToFrac(db float64) float64 {
+	if db <= 0 {
+		return 1.0
+	}
+	return pow2(-db / (20.0 / 3.3219)) // 10^(-db/20) via 2^x
+}
+
+// buildZonesGeneric walks inst bag/gen lists into per-instrument zones.
+func buildZonesGeneric(names []string, bagStart []int, bag []rawBag, gen []rawGen, isInstrument bool) []instrument {
+	out := make([]instrument, len(names))
+	for i, name := range names {
+		start := bagStart[i]
+		end := len(bag)
+		if i+1 < len(bagStart) {
+			end = bagStart[i+1]
+		}
+		var zones []instrumentZone
+		for b := start; b < end && b < len(bag); b++ {
+			genStart := int(bag[b].genNdx)
+			genEnd := len(gen)
+			if b+1 < len(bag) {
+				genEnd = int(bag[b+1].genNdx)
+			}
+			z := instrumentZone{loKey: 0, hiKey: 127, loVel: 0, hiVel: 127, gen: defaultGenerators()}
+			for g := genStart; g < genEnd && g < len(gen); g++ {
+				rg := gen[g]
+				if rg.oper == genOperKeyRange {
+					z.loKey, z.hiKey = int(rg.loByte), int(rg.hiByte)
+					continue
+				}
+				if rg.oper == genOperVelRange {
+					z.loVel, z.hiVel = int(rg.loByte), int(rg.hiByte)
+					continue
+				}
+				applyGen(&z.gen, rg)
+			}
+			zones = append(zones, z)
+		}
+		out[i] = instrument{name: name, zones: zones}
+	}
+	return out
+}
+
+func buildPresets(hdrs []rawPhdr, bag []rawBag, gen []rawGen) []presetRecord {
+	out := make([]presetRecord, len(hdrs))
+	for i, h := range hdrs {
+		start := h.bagStart
+		end := len(bag)
+		if i+1 < len(hdrs) {
+			end = hdrs[i+1].bagStart
+		}
+		var zones []presetZone
+		for b := start; b < end && b < len(bag); b++ {
+			genStart := int(bag[b].genNdx)
+			genEnd := len(gen)
+			if b+1 < len(bag) {
+				genEnd = int(bag[b+1].genNdx)
+			}
+			z := presetZone{loKey: 0, hiKey: 127, loVel: 0, hiVel: 127, instrumentID: -1, gen: defaultGenerators()}
+			for g := genStart; g < genEnd && g < len(gen); g++ {
+				rg := gen[g]
+				switch rg.oper {
+				case genOperKeyRange:
+					z.loKey, z.hiKey = int(rg.loByte), int(rg.hiByte)
+				case genOperVelRange:
+					z.loVel, z.hiVel = int(rg.loByte), int(rg.hiByte)
+				case genOperInstrument:
+					z.instrumentID = int(rg.amount)
+				default:
+					applyGen(&z.gen, rg)
+				}
+			}
+			if z.instrumentID >= 0 {
+				zones = append(zones, z)
+			}
+		}
+		out[i] = presetRecord{name: h.name, program: h.program, bank: h.bank, zones: zones}
+	}
+	return out
+}
@@ -0,0 +1,135 @@
+package soundfont
+
+import "fmt"
+
+// Render synthesizes durS seconds of a (bank, program) preset at the given
+// MIDI note/velocity into a float buffer at sampleRate, ready to be mixed
+// into the same buffer synthesize's oscillators write into before that
+// function's normalization pass.
+func (f *Font) Render(p Preset, midiNote, velocity int, durS float64, outSampleRate int) ([]float64, error) {
+	presetIdx, ok := f.FindPreset(p)
+	if !ok {
+		return nil, fmt.Errorf("no preset for bank=%d program=%d", p.Bank, p.Program)
+	}
+	preset := f.presets[presetIdx]
+
+	zone, ok := selectPresetZone(preset, midiNote, velocity)
+	if !ok {
+		return nil, fmt.Errorf("preset %q has no zone covering note=%d vel=%d", preset.name, midiNote, velocity)
+	}
+	if zone.instrumentID < 0 || zone.instrumentID >= len(f.instrs) {
+		return nil, fmt.Errorf("preset %q zone has no instrument", preset.name)
+	}
+	inst := f.instrs[zone.instrumentID]
+
+	izone, ok := selectInstrumentZone(inst, midiNote, velocity)
+	if !ok {
+		return nil, fmt.Errorf("instrument %q has no zone covering note=%d vel=%d", inst.name, midiNote, velocity)
+	}
+	if izone.gen.sampleID < 0 || izone.gen.sampleID >= len(f.samples) {
+		return nil, fmt.Errorf("instrument %q zone has no sample", inst.name)
+	}
+	sh := f.samples[izone.gen.sampleID]
+
+	samples, err := f.sampleFor(sh)
+	if err != nil {
+		return nil, err
+	}
+
+	rootKey := int(sh.rootKey)
+	if izone.gen.overridingRoot >= 0 {
+		rootKey = izone.gen.overridingRoot
+	}
+	tuneCents := float64(izone.gen.coarseTuneSemis*100+izone.gen.fineTuneCents) + float64(sh.pitchCorr)
+	ratio := pow2((float64(midiNote-rootKey) + tuneCents/100.0) / 12.0)
+	playbackRate := ratio * float64(sh.sampleRate) / float64(outSampleRate)
+
+	amp := dbToFrac(float64(izone.gen.attenuationCb) / 10.0)
+	looping := izone.gen.loopMode == 1 || izone.gen.loopMode == 3
+	loopStart := int(sh.startLoop - sh.start)
+	loopEnd := int(sh.endLoop - sh.start)
+
+	frameCount := int(durS * float64(outSampleRate))
+	out := make([]float64, frameCount)
+	holdEnd := izone.gen.volAttackS + izone.gen.volHoldS
+	decayEnd := holdEnd + izone.gen.volDecayS
+
+	srcLen := len(samples)
+	pos := 0.0
+	for i := 0; i < frameCount; i++ {
+		t := float64(i) / float64(outSampleRate)
+
+		idx := int(pos)
+		if looping && loopEnd > loopStart {
+			for idx >= loopEnd {
+				idx -= (loopEnd - loopStart)
+			}
+		}
+		if idx >= srcLen-1 {
+			break
+		}
+		frac := pos - float64(int(pos))
+		s0 := float64(samples[idx])
+		s1 := float64(samples[idx+1])
+		v := (s0 + (s1-s0)*frac) / 32768.0
+
+		out[i] = v * amp * volEnvelope(t, izone.gen.volAttackS, holdEnd, decayEnd, izone.gen.volSustainFrac, durS, izone.gen.volReleaseS)
+		pos += playbackRate
+	}
+	return out, nil
+}
+
+// volEnvelope implements the SF2 volume envelope's attack/hold/decay/sustain
+// stages; release is approximated as a linear fade over the last volReleaseS
+// before durS, reaching 0 at durS, since this renderer doesn't model a
+// separate note-off.
+func volEnvelope(t, attackS, holdEnd, decayEnd, sustainFrac, durS, releaseS float64) float64 {
+	var level float64
+	switch {
+	case t < attackS:
+		if attackS <= 0 {
+			level = 1.0
+		} else {
+			level = t / attackS
+		}
+	case t < holdEnd:
+		level = 1.0
+	case t < decayEnd:
+		span := decayEnd - holdEnd
+		if span <= 0 {
+			level = sustainFrac
+		} else {
+			frac := (t - holdEnd) / span
+			level = 1.0 + (sustainFrac-1.0)*frac
+		}
+	default:
+		level = sustainFrac
+	}
+
+	if releaseS > 0 && t > durS-releaseS {
+		fade := (durS - t) / releaseS
+		if fade < 0 {
+			fade = 0
+		}
+		level *= fade
+	}
+	return level
+}
+
+func selectPresetZone(p presetRecord, key, vel int) (presetZone, bool) {
+	for _, z := range p.zones {
+		if key >= z.loKey && key <= z.hiKey && vel >= z.loVel && vel <= z.hiVel {
+			return z, true
+		}
+	}
+	return presetZone{}, false
+}
+
+func selectInstrumentZone(inst instrument, key, vel int) (instrumentZone, bool) {
+	for _, z := range inst.zones {
+		if key >= z.loKey && key <= z.hiKey && vel >= z.loVel && vel <= z.hiVel {
+			return z, true
+		}
+	}
+	return instrumentZone{}, false
+}
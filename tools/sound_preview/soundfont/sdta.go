@@ -0,0 +1,79 @@
+package soundfont
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/jfreymuth/oggvorbis"
+)
+
+// parseSdta reads the "smpl" (and, for SF3, per-sample Ogg-Vorbis-compressed
+// data referenced via shdr.sampleType's compressed flag) sub-chunk into a
+// flat int16 sample pool. SF3 decompression happens lazily per sample in
+// sampleFor, since shdr isn't parsed yet when sdta is read.
+func (f *Font) parseSdta(data []byte) error {
+	r := bytes.NewReader(data)
+	for r.Len() > 0 {
+		id, size, err := readChunkHeader(r)
+		if err != nil {
+			break
+		}
+		body := make([]byte, size)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return err
+		}
+		if size%2 == 1 {
+			r.Seek(1, io.SeekCurrent)
+		}
+		if id != "smpl" {
+			continue
+		}
+		f.sampleData = make([]int16, len(body)/2)
+		if err := binary.Read(bytes.NewReader(body), binary.LittleEndian, f.sampleData); err != nil {
+			return fmt.Errorf("read smpl: %w", err)
+		}
+	}
+	return nil
+}
+
+// sampleFor returns the 16-bit mono samples for a given sample header,
+// decompressing Ogg-Vorbis data on demand for SF3 banks.
+func (f *Font) sampleFor(sh sampleHeader) ([]int16, error) {
+	if sh.sampleType&sfCompressedFlag == 0 {
+		if int(sh.end) > len(f.sampleData) {
+			return nil, fmt.Errorf("sample %q out of range", sh.name)
+		}
+		return f.sampleData[sh.start:sh.end], nil
+	}
+
+	raw := make([]byte, 0, int(sh.end-sh.start)*2)
+	for _, v := range f.sampleData[sh.start:sh.end] {
+		raw = append(raw, byte(v), byte(v>>8))
+	}
+	pcm, _, err := oggvorbis.ReadAll(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("sf3 ogg decode %q: %w", sh.name, err)
+	}
+	out := make([]int16, len(pcm))
+	for i, v := range pcm {
+		out[i] = int16(clampFloat(v) * 32767.0)
+	}
+	return out, nil
+}
+
+func clampFloat(v float32) float32 {
+	if v > 1 {
+		return 1
+	}
+	if v < -1 {
+		return -1
+	}
+	return v
+}
+
+func pow2(x float64) float64 {
+	return math.Exp2(x)
+}
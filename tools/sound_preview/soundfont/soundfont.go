@@ -0,0 +1,211 @@
+// Package soundfont provides a minimal SF2/SF3 reader sufficient to resolve
+// a (bank, program, key) triple down to a sample and the generator
+// parameters needed to render it, so synth alerts can be layered on top of
+// sampled instruments instead of raw oscillators.
+package soundfont
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Preset identifies an SF2 preset by its bank/program pair, matching the
+// General MIDI addressing scheme.
+type Preset struct {
+	Bank    int
+	Program int
+}
+
+// sampleHeader mirrors the fixed-size SF2 "shdr" record.
+type sampleHeader struct {
+	name       string
+	start      uint32
+	end        uint32
+	startLoop  uint32
+	endLoop    uint32
+	sampleRate uint32
+	rootKey    byte
+	pitchCorr  int8
+	sampleType uint16
+}
+
+// generators resolved for one (preset zone + instrument zone) pairing.
+// Only the generators this package's Render understands are kept; see the
+// request's minimum-implementation list.
+type generators struct {
+	sampleID        int
+	overridingRoot  int // -1 means "use the sample header's root key"
+	fineTuneCents   int
+	coarseTuneSemis int
+	attenuationCb   int // centibels
+	volAttackS      float64
+	volHoldS        float64
+	volDecayS       float64
+	volSustainFrac  float64 // 0..1, fraction of peak remaining
+	volReleaseS     float64
+	loopMode        int // 0=no loop, 1/3=loop while held, 2=unused
+}
+
+func defaultGenerators() generators {
+	return generators{
+		sampleID:       -1,
+		overridingRoot: -1,
+		volAttackS:     0.001,
+		volHoldS:       0,
+		volDecayS:      0,
+		volSustainFrac: 1.0,
+		volReleaseS:    0.05,
+	}
+}
+
+// instrumentZone pairs a key/velocity range with resolved generators.
+type instrumentZone struct {
+	loKey, hiKey int
+	loVel, hiVel int
+	gen          generators
+}
+
+type instrument struct {
+	name  string
+	zones []instrumentZone
+}
+
+// presetZone points a preset's key/velocity range at an instrument, carrying
+// the preset-level generators it sets. Render does not apply them; only the
+// instrument zone's generators shape the rendered note.
+type presetZone struct {
+	loKey, hiKey int
+	loVel, hiVel int
+	instrumentID int
+	gen          generators
+}
+
+type presetRecord struct {
+	name    string
+	program int
+	bank    int
+	zones   []presetZone
+}
+
+// Font is a parsed SF2/SF3 bank. SF3 differs from SF2 only in that sample
+// data is Ogg-Vorbis compressed (flagged per-sample via sampleType's
+// sfCompressedFlag bit); sampleFor decompresses those samples on demand when
+// Render asks for them, so Render never needs to know the difference.
+type Font struct {
+	presets    []presetRecord
+	instrs     []instrument
+	samples    []sampleHeader
+	sampleData []int16 // shared 16-bit sample pool, indexed by shdr offsets
+}
+
+const (
+	sfSampleTypeMono  = 1
+	sfCompressedFlag  = 0x10 // SF3 extension: sample data is Ogg-Vorbis
+	genOperSampleID   = 53
+	genOperOverrRoot  = 58
+	genOperFineTune   = 52
+	genOperCoarseTune = 51
+	genOperAttenuate  = 48
+	genOperVolAttack  = 34
+	genOperVolHold    = 35
+	genOperVolDecay   = 36
+	genOperVolSustain = 37
+	genOperVolRelease = 38
+	genOperSampleMode = 54
+	genOperKeyRange   = 43
+	genOperVelRange   = 44
+	genOperInstrument = 41
+)
+
+// Load parses an SF2 or SF3 file from disk.
+func Load(path string) (*Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse parses SF2/SF3 bytes already in memory.
+func Parse(data []byte) (*Font, error) {
+	r := bytes.NewReader(data)
+
+	id, size, err := readChunkHeader(r)
+	if err != nil {
+		return nil, fmt.Errorf("read riff header: %w", err)
+	}
+	if id != "RIFF" {
+		return nil, fmt.Errorf("not a RIFF file")
+	}
+	form := make([]byte, 4)
+	if _, err := io.ReadFull(r, form); err != nil {
+		return nil, err
+	}
+	if string(form) != "sfbk" {
+		return nil, fmt.Errorf("not an sfbk soundfont (form %q)", form)
+	}
+
+	f := &Font{}
+	end := int64(size) + 8
+	var pdtaRaw, sdtaRaw []byte
+
+	for {
+		pos, _ := r.Seek(0, io.SeekCurrent)
+		if pos >= end {
+			break
+		}
+		listID, listSize, err := readChunkHeader(r)
+		if err != nil {
+			break
+		}
+		body := make([]byte, listSize)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return nil, fmt.Errorf("read %s chunk: %w", listID, err)
+		}
+		if listSize%2 == 1 {
+			r.Seek(1, io.SeekCurrent) // RIFF chunks are word-aligned
+		}
+		if listID != "LIST" || len(body) < 4 {
+			continue
+		}
+		switch string(body[:4]) {
+		case "pdta":
+			pdtaRaw = body[4:]
+		case "sdta":
+			sdtaRaw = body[4:]
+		}
+	}
+
+	if err := f.parseSdta(sdtaRaw); err != nil {
+		return nil, fmt.Errorf("parse sdta: %w", err)
+	}
+	if err := f.parsePdta(pdtaRaw); err != nil {
+		return nil, fmt.Errorf("parse pdta: %w", err)
+	}
+	return f, nil
+}
+
+func readChunkHeader(r *bytes.Reader) (string, uint32, error) {
+	idb := make([]byte, 4)
+	if _, err := io.ReadFull(r, idb); err != nil {
+		return "", 0, err
+	}
+	var size uint32
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return "", 0, err
+	}
+	return string(idb), size, nil
+}
+
+// FindPreset looks up a preset by bank/program. Returns false if absent.
+func (f *Font) FindPreset(p Preset) (int, bool) {
+	for i, pr := range f.presets {
+		if pr.bank == p.Bank && pr.program == p.Program {
+			return i, true
+		}
+	}
+	return 0, false
+}
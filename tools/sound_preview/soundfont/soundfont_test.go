@@ -0,0 +1,81 @@
+package soundfont
+
+import "testing"
+
+func TestFindPreset(t *testing.T) {
+	f := &Font{presets: []presetRecord{
+		{name: "Piano", bank: 0, program: 0},
+		{name: "Strings", bank: 0, program: 48},
+	}}
+
+	if idx, ok := f.FindPreset(Preset{Bank: 0, Program: 48}); !ok || idx != 1 {
+		t.Fatalf("FindPreset(0,48) = (%d, %v), want (1, true)", idx, ok)
+	}
+	if _, ok := f.FindPreset(Preset{Bank: 1, Program: 0}); ok {
+		t.Fatalf("FindPreset(1,0) should not match any preset")
+	}
+}
+
+func TestSelectPresetZone(t *testing.T) {
+	p := presetRecord{zones: []presetZone{
+		{loKey: 0, hiKey: 59, loVel: 0, hiVel: 127, instrumentID: 0},
+		{loKey: 60, hiKey: 127, loVel: 0, hiVel: 127, instrumentID: 1},
+	}}
+
+	if z, ok := selectPresetZone(p, 69, 100); !ok || z.instrumentID != 1 {
+		t.Fatalf("selectPresetZone(69,100) = (%+v, %v), want instrumentID 1", z, ok)
+	}
+	if _, ok := selectPresetZone(p, 200, 100); ok {
+		t.Fatalf("selectPresetZone should reject a key outside every zone")
+	}
+}
+
+func TestSelectInstrumentZone(t *testing.T) {
+	inst := instrument{zones: []instrumentZone{
+		{loKey: 0, hiKey: 127, loVel: 0, hiVel: 63, gen: generators{sampleID: 1}},
+		{loKey: 0, hiKey: 127, loVel: 64, hiVel: 127, gen: generators{sampleID: 2}},
+	}}
+
+	if z, ok := selectInstrumentZone(inst, 60, 127); !ok || z.gen.sampleID != 2 {
+		t.Fatalf("selectInstrumentZone(60,127) = (%+v, %v), want sampleID 2", z, ok)
+	}
+}
+
+func TestDbToFrac(t *testing.T) {
+	if got := dbToFrac(0); got != 1.0 {
+		t.Fatalf("dbToFrac(0) = %v, want 1.0", got)
+	}
+	if got := dbToFrac(-5); got != 1.0 {
+		t.Fatalf("dbToFrac(-5) = %v, want 1.0 (no attenuation below 0dB)", got)
+	}
+	// 6.0206dB of attenuation is a 2x amplitude drop.
+	if got := dbToFrac(6.0206); got < 0.49 || got > 0.51 {
+		t.Fatalf("dbToFrac(6.0206) = %v, want ~0.5", got)
+	}
+}
+
+func TestTimecentsToSeconds(t *testing.T) {
+	if got := timecentsToSeconds(-12000); got != 0 {
+		t.Fatalf("timecentsToSeconds(-12000) = %v, want 0", got)
+	}
+	if got := timecentsToSeconds(0); got != 1.0 {
+		t.Fatalf("timecentsToSeconds(0) = %v, want 1.0", got)
+	}
+}
+
+func TestVolEnvelopeStages(t *testing.T) {
+	const attackS, holdEnd, decayEnd, sustainFrac, durS, releaseS = 0.1, 0.2, 0.3, 0.5, 10.0, 0.05
+
+	if got := volEnvelope(0.05, attackS, holdEnd, decayEnd, sustainFrac, durS, releaseS); got >= 1.0 {
+		t.Fatalf("mid-attack level %v should be < 1.0", got)
+	}
+	if got := volEnvelope(0.15, attackS, holdEnd, decayEnd, sustainFrac, durS, releaseS); got != 1.0 {
+		t.Fatalf("hold-stage level = %v, want 1.0", got)
+	}
+	if got := volEnvelope(0.3, attackS, holdEnd, decayEnd, sustainFrac, durS, releaseS); got != sustainFrac {
+		t.Fatalf("post-decay sustain level = %v, want %v", got, sustainFrac)
+	}
+	if got := volEnvelope(durS, attackS, holdEnd, decayEnd, sustainFrac, durS, releaseS); got != 0 {
+		t.Fatalf("level at durS should fade to 0 over the release tail, got %v", got)
+	}
+}